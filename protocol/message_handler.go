@@ -4,6 +4,7 @@ import (
 	"crypto/ecdsa"
 	"encoding/hex"
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
@@ -20,17 +21,44 @@ const (
 	requestAddressForTransactionDeclinedMessage = "Request address for transaction declined"
 )
 
+// ValidationTimingHook is called after a received message has been validated,
+// with the type of the message and how long the validation took.
+type ValidationTimingHook func(protobuf.ApplicationMetadataMessage_Type, time.Duration)
+
 type MessageHandler struct {
-	identity    *ecdsa.PrivateKey
-	persistence *sqlitePersistence
-	logger      *zap.Logger
+	identity                     *ecdsa.PrivateKey
+	persistence                  *sqlitePersistence
+	logger                       *zap.Logger
+	validationTimingHook         ValidationTimingHook
+	chatMessageValidationOptions ChatMessageValidationOptions
 }
 
-func newMessageHandler(identity *ecdsa.PrivateKey, logger *zap.Logger, persistence *sqlitePersistence) *MessageHandler {
+func newMessageHandler(identity *ecdsa.PrivateKey, logger *zap.Logger, persistence *sqlitePersistence, validationTimingHook ValidationTimingHook, chatMessageValidationOptions ChatMessageValidationOptions) *MessageHandler {
 	return &MessageHandler{
-		identity:    identity,
-		persistence: persistence,
-		logger:      logger}
+		identity:                     identity,
+		persistence:                  persistence,
+		logger:                       logger,
+		validationTimingHook:         validationTimingHook,
+		chatMessageValidationOptions: chatMessageValidationOptions}
+}
+
+// validateWithTimingHook runs validator, reporting its duration to hook if
+// it's not nil.
+func validateWithTimingHook(hook ValidationTimingHook, messageType protobuf.ApplicationMetadataMessage_Type, validator func() error) error {
+	if hook == nil {
+		return validator()
+	}
+
+	start := time.Now()
+	err := validator()
+	hook(messageType, time.Since(start))
+	return err
+}
+
+// validate runs the validation of a received message, reporting its duration
+// to the validation timing hook if one is configured.
+func (m *MessageHandler) validate(messageType protobuf.ApplicationMetadataMessage_Type, validator func() error) error {
+	return validateWithTimingHook(m.validationTimingHook, messageType, validator)
 }
 
 // HandleMembershipUpdate updates a Chat instance according to the membership updates.
@@ -38,25 +66,33 @@ func newMessageHandler(identity *ecdsa.PrivateKey, logger *zap.Logger, persisten
 // Finally, the Chat is updated with the new group events.
 func (m *MessageHandler) HandleMembershipUpdate(messageState *ReceivedMessageState, chat *Chat, rawMembershipUpdate protobuf.MembershipUpdateMessage, translations map[protobuf.MembershipUpdateEvent_EventType]string) error {
 	var group *v1protocol.Group
+	var message *v1protocol.MembershipUpdateMessage
 	var err error
 
 	logger := m.logger.With(zap.String("site", "HandleMembershipUpdate"))
 
-	message, err := v1protocol.MembershipUpdateMessageFromProtobuf(&rawMembershipUpdate)
-	if err != nil {
-		return err
+	// Decoding verifies the signature of each event, so it's timed as part
+	// of the validation
+	err = m.validate(protobuf.ApplicationMetadataMessage_MEMBERSHIP_UPDATE_MESSAGE, func() error {
+		// Bail out early before doing any work on an oversized message
+		if err := validateMembershipUpdateEventsCount(len(rawMembershipUpdate.Events)); err != nil {
+			return err
+		}
 
-	}
+		var err error
+		message, err = v1protocol.MembershipUpdateMessageFromProtobuf(&rawMembershipUpdate)
+		if err != nil {
+			return err
+		}
 
-	if err := ValidateMembershipUpdateMessage(message, messageState.Timesource.GetCurrentTime()); err != nil {
+		return ValidateMembershipUpdateMessage(message, messageState.Timesource.GetCurrentTime())
+	})
+	if err != nil {
 		logger.Warn("failed to validate message", zap.Error(err))
 		return err
 	}
 
 	if chat == nil {
-		if len(message.Events) == 0 {
-			return errors.New("can't create new group chat without events")
-		}
 		group, err = v1protocol.NewGroupWithEvents(message.ChatID, message.Events)
 		if err != nil {
 			return err
@@ -172,6 +208,15 @@ func (m *MessageHandler) handleCommandMessage(state *ReceivedMessageState, messa
 }
 
 func (m *MessageHandler) HandleSyncInstallationContact(state *ReceivedMessageState, message protobuf.SyncInstallationContact) error {
+	logger := m.logger.With(zap.String("site", "HandleSyncInstallationContact"))
+	err := m.validate(protobuf.ApplicationMetadataMessage_SYNC_INSTALLATION_CONTACT, func() error {
+		return ValidateReceivedSyncInstallationContact(&message, state.CurrentMessageState.WhisperTimestamp)
+	})
+	if err != nil {
+		logger.Warn("failed to validate message", zap.Error(err))
+		return err
+	}
+
 	chat, ok := state.AllChats[state.CurrentMessageState.Contact.ID]
 	if !ok {
 		chat = OneToOneFromPublicKey(state.CurrentMessageState.PublicKey, state.Timesource)
@@ -268,7 +313,10 @@ func (m *MessageHandler) HandleContactUpdate(state *ReceivedMessageState, messag
 
 func (m *MessageHandler) HandlePairInstallation(state *ReceivedMessageState, message protobuf.PairInstallation) error {
 	logger := m.logger.With(zap.String("site", "HandlePairInstallation"))
-	if err := ValidateReceivedPairInstallation(&message, state.CurrentMessageState.WhisperTimestamp); err != nil {
+	err := m.validate(protobuf.ApplicationMetadataMessage_PAIR_INSTALLATION, func() error {
+		return ValidateReceivedPairInstallation(&message, state.CurrentMessageState.WhisperTimestamp)
+	})
+	if err != nil {
 		logger.Warn("failed to validate message", zap.Error(err))
 		return err
 	}
@@ -292,7 +340,13 @@ func (m *MessageHandler) HandlePairInstallation(state *ReceivedMessageState, mes
 
 func (m *MessageHandler) HandleChatMessage(state *ReceivedMessageState) error {
 	logger := m.logger.With(zap.String("site", "handleChatMessage"))
-	if err := ValidateReceivedChatMessage(&state.CurrentMessageState.Message, state.CurrentMessageState.WhisperTimestamp); err != nil {
+	err := m.validate(protobuf.ApplicationMetadataMessage_CHAT_MESSAGE, func() error {
+		if err := ValidateReceivedChatMessageWithOptions(&state.CurrentMessageState.Message, state.CurrentMessageState.WhisperTimestamp, m.chatMessageValidationOptions); err != nil {
+			return err
+		}
+		return ValidateReceivedChatMessageID(&state.CurrentMessageState.Message, state.CurrentMessageState.MessageID)
+	})
+	if err != nil {
 		logger.Warn("failed to validate message", zap.Error(err))
 		return err
 	}
@@ -306,7 +360,7 @@ func (m *MessageHandler) HandleChatMessage(state *ReceivedMessageState) error {
 		WhisperTimestamp: state.CurrentMessageState.WhisperTimestamp,
 	}
 
-	err := receivedMessage.PrepareContent()
+	err = receivedMessage.PrepareContent()
 	if err != nil {
 		return fmt.Errorf("failed to prepare message content: %v", err)
 	}
@@ -365,7 +419,9 @@ func (m *MessageHandler) HandleChatMessage(state *ReceivedMessageState) error {
 }
 
 func (m *MessageHandler) HandleRequestAddressForTransaction(messageState *ReceivedMessageState, command protobuf.RequestAddressForTransaction) error {
-	err := ValidateReceivedRequestAddressForTransaction(&command, messageState.CurrentMessageState.WhisperTimestamp)
+	err := m.validate(protobuf.ApplicationMetadataMessage_REQUEST_ADDRESS_FOR_TRANSACTION, func() error {
+		return ValidateReceivedRequestAddressForTransaction(&command, messageState.CurrentMessageState.WhisperTimestamp)
+	})
 	if err != nil {
 		return err
 	}
@@ -389,7 +445,9 @@ func (m *MessageHandler) HandleRequestAddressForTransaction(messageState *Receiv
 }
 
 func (m *MessageHandler) HandleRequestTransaction(messageState *ReceivedMessageState, command protobuf.RequestTransaction) error {
-	err := ValidateReceivedRequestTransaction(&command, messageState.CurrentMessageState.WhisperTimestamp)
+	err := m.validate(protobuf.ApplicationMetadataMessage_REQUEST_TRANSACTION, func() error {
+		return ValidateReceivedRequestTransaction(&command, messageState.CurrentMessageState.WhisperTimestamp)
+	})
 	if err != nil {
 		return err
 	}
@@ -414,7 +472,9 @@ func (m *MessageHandler) HandleRequestTransaction(messageState *ReceivedMessageS
 }
 
 func (m *MessageHandler) HandleAcceptRequestAddressForTransaction(messageState *ReceivedMessageState, command protobuf.AcceptRequestAddressForTransaction) error {
-	err := ValidateReceivedAcceptRequestAddressForTransaction(&command, messageState.CurrentMessageState.WhisperTimestamp)
+	err := m.validate(protobuf.ApplicationMetadataMessage_ACCEPT_REQUEST_ADDRESS_FOR_TRANSACTION, func() error {
+		return ValidateReceivedAcceptRequestAddressForTransaction(&command, messageState.CurrentMessageState.WhisperTimestamp)
+	})
 	if err != nil {
 		return err
 	}
@@ -463,7 +523,9 @@ func (m *MessageHandler) HandleAcceptRequestAddressForTransaction(messageState *
 }
 
 func (m *MessageHandler) HandleSendTransaction(messageState *ReceivedMessageState, command protobuf.SendTransaction) error {
-	err := ValidateReceivedSendTransaction(&command, messageState.CurrentMessageState.WhisperTimestamp)
+	err := m.validate(protobuf.ApplicationMetadataMessage_SEND_TRANSACTION, func() error {
+		return ValidateReceivedSendTransaction(&command, messageState.CurrentMessageState.WhisperTimestamp)
+	})
 	if err != nil {
 		return err
 	}
@@ -483,7 +545,9 @@ func (m *MessageHandler) HandleSendTransaction(messageState *ReceivedMessageStat
 }
 
 func (m *MessageHandler) HandleDeclineRequestAddressForTransaction(messageState *ReceivedMessageState, command protobuf.DeclineRequestAddressForTransaction) error {
-	err := ValidateReceivedDeclineRequestAddressForTransaction(&command, messageState.CurrentMessageState.WhisperTimestamp)
+	err := m.validate(protobuf.ApplicationMetadataMessage_DECLINE_REQUEST_ADDRESS_FOR_TRANSACTION, func() error {
+		return ValidateReceivedDeclineRequestAddressForTransaction(&command, messageState.CurrentMessageState.WhisperTimestamp)
+	})
 	if err != nil {
 		return err
 	}
@@ -523,7 +587,9 @@ func (m *MessageHandler) HandleDeclineRequestAddressForTransaction(messageState
 }
 
 func (m *MessageHandler) HandleDeclineRequestTransaction(messageState *ReceivedMessageState, command protobuf.DeclineRequestTransaction) error {
-	err := ValidateReceivedDeclineRequestTransaction(&command, messageState.CurrentMessageState.WhisperTimestamp)
+	err := m.validate(protobuf.ApplicationMetadataMessage_DECLINE_REQUEST_TRANSACTION, func() error {
+		return ValidateReceivedDeclineRequestTransaction(&command, messageState.CurrentMessageState.WhisperTimestamp)
+	})
 	if err != nil {
 		return err
 	}
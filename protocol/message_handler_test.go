@@ -2,9 +2,12 @@ package protocol
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
 
+	"github.com/status-im/status-go/protocol/protobuf"
 	v1protocol "github.com/status-im/status-go/protocol/v1"
 )
 
@@ -78,3 +81,53 @@ func (s *EventToSystemMessageSuite) TestRun() {
 	}
 
 }
+
+func TestMessageHandlerValidationSuite(t *testing.T) {
+	suite.Run(t, new(MessageHandlerValidationSuite))
+}
+
+type MessageHandlerValidationSuite struct {
+	suite.Suite
+}
+
+func (s *MessageHandlerValidationSuite) TestValidationTimingHook() {
+	var recorded []protobuf.ApplicationMetadataMessage_Type
+	hook := func(messageType protobuf.ApplicationMetadataMessage_Type, duration time.Duration) {
+		s.True(duration >= 0)
+		recorded = append(recorded, messageType)
+	}
+	handler := newMessageHandler(nil, zap.NewNop(), nil, hook, ChatMessageValidationOptions{})
+
+	// The messages are invalid, so the handlers return before persisting anything
+	state := &ReceivedMessageState{
+		CurrentMessageState: &CurrentMessageState{WhisperTimestamp: 30},
+		Timesource:          &testTimeSource{},
+	}
+	s.Error(handler.HandleChatMessage(state))
+	s.Error(handler.HandleMembershipUpdate(state, nil, protobuf.MembershipUpdateMessage{}, nil))
+	s.Error(handler.HandlePairInstallation(state, protobuf.PairInstallation{}))
+	s.Error(handler.HandleRequestAddressForTransaction(state, protobuf.RequestAddressForTransaction{}))
+	s.Error(handler.HandleSendTransaction(state, protobuf.SendTransaction{}))
+
+	s.Equal([]protobuf.ApplicationMetadataMessage_Type{
+		protobuf.ApplicationMetadataMessage_CHAT_MESSAGE,
+		protobuf.ApplicationMetadataMessage_MEMBERSHIP_UPDATE_MESSAGE,
+		protobuf.ApplicationMetadataMessage_PAIR_INSTALLATION,
+		protobuf.ApplicationMetadataMessage_REQUEST_ADDRESS_FOR_TRANSACTION,
+		protobuf.ApplicationMetadataMessage_SEND_TRANSACTION,
+	}, recorded)
+}
+
+func (s *MessageHandlerValidationSuite) TestHandleMembershipUpdateTooManyEvents() {
+	handler := newMessageHandler(nil, zap.NewNop(), nil, nil, ChatMessageValidationOptions{})
+	state := &ReceivedMessageState{
+		CurrentMessageState: &CurrentMessageState{WhisperTimestamp: 30},
+		Timesource:          &testTimeSource{},
+	}
+
+	// The events can't be decoded, so this only passes if the count is checked before decoding
+	events := make([][]byte, MaxMembershipUpdateEvents+1)
+
+	err := handler.HandleMembershipUpdate(state, nil, protobuf.MembershipUpdateMessage{Events: events}, nil)
+	s.Equal(ErrTooManyMembershipEvents, err)
+}
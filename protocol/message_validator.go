@@ -2,55 +2,288 @@ package protocol
 
 import (
 	"errors"
-	"strconv"
+	"regexp"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/google/uuid"
+	"github.com/status-im/markdown"
+	"github.com/status-im/markdown/ast"
+
+	"github.com/status-im/status-go/eth-node/crypto"
+	"github.com/status-im/status-go/eth-node/types"
 	"github.com/status-im/status-go/protocol/protobuf"
 	"github.com/status-im/status-go/protocol/v1"
 )
 
+var (
+	ErrZeroClock     = errors.New("clock can't be 0")
+	ErrClockTooHigh  = errors.New("clock value too high")
+	ErrZeroTimestamp = errors.New("timestamp can't be 0")
+
+	ErrEmptyText                 = errors.New("text can't be empty")
+	ErrEmptyChatID               = errors.New("chatId can't be empty")
+	ErrUnknownContentType        = errors.New("unknown content type")
+	ErrUnknownMessageType        = errors.New("unknown message type")
+	ErrNoStickerContent          = errors.New("no sticker content")
+	ErrNoStickerHash             = errors.New("sticker hash not set")
+	ErrTransactionCommandMessage = errors.New("can't receive request address for transaction from others")
+	ErrSelfReply                 = errors.New("message can't reply to itself")
+	ErrUnexpectedPayload         = errors.New("payload doesn't match content type")
+	ErrBidiOverride              = errors.New("text contains bidirectional override characters")
+	ErrTooManyMentions           = errors.New("too many mentions")
+	ErrTextTooLong               = errors.New("text too long")
+	ErrChatNotAllowed            = errors.New("chat not allowed")
+	ErrUnnormalizedText          = errors.New("text has leading or trailing whitespace")
+	ErrLinkOnlyMessage           = errors.New("text only contains links")
+	ErrTooManyLines              = errors.New("text has too many lines")
+	ErrBannedKeyword             = errors.New("text contains a banned keyword")
+
+	ErrTooManyMembershipEvents  = errors.New("too many membership update events")
+	ErrDuplicateMembershipEvent = errors.New("duplicate membership update event")
+	ErrEmptyMembershipUpdate    = errors.New("membership update has no events")
+	ErrClockTooSmall            = errors.New("clock value too small")
+
+	ErrInvalidPublicKey = errors.New("invalid public key")
+
+	ErrEmptyValue            = errors.New("value can't be empty")
+	ErrMalformedValue        = errors.New("value is not a plain decimal number")
+	ErrEmptyAddress          = errors.New("address can't be empty")
+	ErrEmptyMessageID        = errors.New("messageID can't be empty")
+	ErrEmptyTransactionHash  = errors.New("transaction hash can't be empty")
+	ErrNilSignature          = errors.New("signature can't be nil")
+	ErrZeroAddress           = errors.New("address can't be the zero address")
+	ErrDestinationIsContract = errors.New("address can't be the token contract address")
+
+	ErrEmptyName                = errors.New("name can't be empty")
+	ErrEmptyDeviceType          = errors.New("device type can't be empty")
+	ErrEmptyInstallationID      = errors.New("installationId can't be empty")
+	ErrInvalidInstallationID    = errors.New("installationId is not a valid uuid")
+	ErrInvalidDeviceName        = errors.New("invalid device name")
+	ErrNonMonotonicPairingClock = errors.New("pairing clock not greater than last seen")
+
+	ErrInvalidSignatureLength     = errors.New("signature must be 65 bytes long")
+	ErrInvalidSignatureRecoveryID = errors.New("invalid signature recovery id")
+	ErrDuplicateResponseID        = errors.New("duplicate transaction response id")
+
+	ErrUnsupportedMessageType = errors.New("no validator for message type")
+	ErrOtherValidationFailure = errors.New("other validation failure")
+)
+
+// transactionSignatureLength is the length of a signature in the [R || S || V] format
+const transactionSignatureLength = 65
+
+// maxDeviceNameLength is the maximum length in runes of the name of a paired device
+const maxDeviceNameLength = 64
+
+const (
+	compressedPublicKeyLength   = 33
+	uncompressedPublicKeyLength = 65
+)
+
+// ValidatePublicKey checks that publicKey is a 0x prefixed, hex encoded
+// secp256k1 public key, either compressed or uncompressed, and that the
+// point is on the curve
+func ValidatePublicKey(publicKey string) error {
+	b, err := types.DecodeHex(publicKey)
+	if err != nil {
+		return ErrInvalidPublicKey
+	}
+
+	switch len(b) {
+	case compressedPublicKeyLength:
+		_, err = crypto.DecompressPubkey(b)
+	case uncompressedPublicKeyLength:
+		_, err = crypto.UnmarshalPubkey(b)
+	default:
+		return ErrInvalidPublicKey
+	}
+	if err != nil {
+		return ErrInvalidPublicKey
+	}
+
+	return nil
+}
+
+// isZeroAddress returns whether address is the hex encoded 0x000...0 address
+func isZeroAddress(address string) bool {
+	return types.IsHexAddress(address) && types.HexToAddress(address) == types.Address{}
+}
+
+// transactionValueRegexp matches plain decimal amounts, without sign,
+// exponent or separators
+var transactionValueRegexp = regexp.MustCompile(`^([0-9]+\.?[0-9]*|\.[0-9]+)$`)
+
+// MaxMembershipUpdateEvents is the maximum number of events accepted in a
+// single membership update message
+var MaxMembershipUpdateEvents = 5000
+
+// ValidationErrors aggregates several validation failures into a single error.
+// errors.Is and errors.As match against each of the contained errors.
+type ValidationErrors []error
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e ValidationErrors) Unwrap() []error {
+	return e
+}
+
+func (e ValidationErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e ValidationErrors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
 // maxWhisperDrift is how many milliseconds we allow the clock value to differ
 // from whisperTimestamp
 const maxWhisperFutureDriftMs uint64 = 120000
 
 func validateClockValue(clock uint64, whisperTimestamp uint64) error {
 	if clock == 0 {
-		return errors.New("clock can't be 0")
+		return ErrZeroClock
 	}
 
 	if clock > whisperTimestamp && clock-whisperTimestamp > maxWhisperFutureDriftMs {
-		return errors.New("clock value too high")
+		return ErrClockTooHigh
+	}
+
+	return nil
+}
+
+// MinPlausibleClockValue is the smallest clock accepted for messages whose
+// clock is derived from the wall clock, defaults to the 1st of January 2019 in ms
+var MinPlausibleClockValue uint64 = 1546300800000
+
+// validatePlausibleClockValue is validateClockValue for message types that opt in
+// to the wall-clock floor. Lamport clocks for those start from the current time
+// in ms, so anything below MinPlausibleClockValue comes from a broken client.
+func validatePlausibleClockValue(clock uint64, whisperTimestamp uint64) error {
+	if err := validateClockValue(clock, whisperTimestamp); err != nil {
+		return err
+	}
+
+	if clock < MinPlausibleClockValue {
+		return ErrClockTooSmall
 	}
 
 	return nil
 }
 
+// validateMembershipUpdateEventsCount checks the number of events of a
+// membership update, it's cheap enough to be called before decoding
+func validateMembershipUpdateEventsCount(count int) error {
+	if count > MaxMembershipUpdateEvents {
+		return ErrTooManyMembershipEvents
+	}
+	return nil
+}
+
 func ValidateMembershipUpdateMessage(message *protocol.MembershipUpdateMessage, timeNowMs uint64) error {
+	if len(message.Events) == 0 {
+		return ErrEmptyMembershipUpdate
+	}
 
+	if err := validateMembershipUpdateEventsCount(len(message.Events)); err != nil {
+		return err
+	}
+
+	// Events are identified by their signature
+	signatures := make(map[string]bool, len(message.Events))
 	for _, e := range message.Events {
 		if err := validateClockValue(e.ClockValue, timeNowMs); err != nil {
 			return err
 		}
 
+		for _, member := range e.Members {
+			if err := ValidatePublicKey(member); err != nil {
+				return err
+			}
+		}
+
+		if signatures[string(e.Signature)] {
+			return ErrDuplicateMembershipEvent
+		}
+		signatures[string(e.Signature)] = true
 	}
 	return nil
 }
 
+// InstallationClockLookup returns the clock of the last pairing message seen
+// for an installation, ok is false if there's none
+type InstallationClockLookup func(installationID string) (clock uint64, ok bool)
+
 func ValidateReceivedPairInstallation(message *protobuf.PairInstallation, whisperTimestamp uint64) error {
-	if err := validateClockValue(message.Clock, whisperTimestamp); err != nil {
+	return ValidateReceivedPairInstallationWithClockLookup(message, whisperTimestamp, nil)
+}
+
+// ValidateReceivedPairInstallationWithClockLookup is ValidateReceivedPairInstallation,
+// also rejecting messages that don't advance the clock of an installation seen
+// before, when lastSeenClock is not nil
+func ValidateReceivedPairInstallationWithClockLookup(message *protobuf.PairInstallation, whisperTimestamp uint64, lastSeenClock InstallationClockLookup) error {
+	if err := validatePlausibleClockValue(message.Clock, whisperTimestamp); err != nil {
 		return err
 	}
 
-	if len(strings.TrimSpace(message.Name)) == 0 {
-		return errors.New("name can't be empty")
+	name := strings.TrimSpace(message.Name)
+	if len(name) == 0 {
+		return ErrEmptyName
+	}
+
+	// Format characters such as zero width joiners are used by emoji
+	// sequences, so only control characters and bidi overrides are rejected
+	if utf8.RuneCountInString(name) > maxDeviceNameLength || strings.IndexFunc(name, func(r rune) bool { return unicode.IsControl(r) || isBidiOverride(r) }) != -1 {
+		return ErrInvalidDeviceName
 	}
 
 	if len(strings.TrimSpace(message.DeviceType)) == 0 {
-		return errors.New("device type can't be empty")
+		return ErrEmptyDeviceType
 	}
 
 	if len(strings.TrimSpace(message.InstallationId)) == 0 {
-		return errors.New("installationId can't be empty")
+		return ErrEmptyInstallationID
+	}
+
+	// Clients generate either v4 or v5 UUIDs, so any version is accepted
+	if _, err := uuid.Parse(message.InstallationId); err != nil {
+		return ErrInvalidInstallationID
+	}
+
+	if lastSeenClock != nil {
+		if clock, ok := lastSeenClock(message.InstallationId); ok && message.Clock <= clock {
+			return ErrNonMonotonicPairingClock
+		}
+	}
+
+	return nil
+}
+
+func ValidateReceivedSyncInstallationContact(message *protobuf.SyncInstallationContact, whisperTimestamp uint64) error {
+	if err := validateClockValue(message.Clock, whisperTimestamp); err != nil {
+		return err
+	}
+
+	if err := ValidatePublicKey(message.Id); err != nil {
+		return err
 	}
 
 	return nil
@@ -62,11 +295,22 @@ func ValidateReceivedSendTransaction(message *protobuf.SendTransaction, whisperT
 	}
 
 	if len(strings.TrimSpace(message.TransactionHash)) == 0 {
-		return errors.New("transaction hash can't be empty")
+		return ErrEmptyTransactionHash
 	}
 
 	if message.Signature == nil {
-		return errors.New("signature can't be nil")
+		return ErrNilSignature
+	}
+
+	if len(message.Signature) != transactionSignatureLength {
+		return ErrInvalidSignatureLength
+	}
+
+	// V is 27/28 for signatures produced by personal_sign, or 0/1 as in the
+	// yellow paper, which is converted to 27/28 before verification
+	v := message.Signature[transactionSignatureLength-1]
+	if v != 0 && v != 1 && v != 27 && v != 28 {
+		return ErrInvalidSignatureRecoveryID
 	}
 
 	return nil
@@ -78,12 +322,11 @@ func ValidateReceivedRequestAddressForTransaction(message *protobuf.RequestAddre
 	}
 
 	if len(strings.TrimSpace(message.Value)) == 0 {
-		return errors.New("value can't be empty")
+		return ErrEmptyValue
 	}
 
-	_, err := strconv.ParseFloat(message.Value, 64)
-	if err != nil {
-		return err
+	if !transactionValueRegexp.MatchString(message.Value) {
+		return ErrMalformedValue
 	}
 
 	return nil
@@ -95,16 +338,23 @@ func ValidateReceivedRequestTransaction(message *protobuf.RequestTransaction, wh
 	}
 
 	if len(strings.TrimSpace(message.Value)) == 0 {
-		return errors.New("value can't be empty")
+		return ErrEmptyValue
 	}
 
 	if len(strings.TrimSpace(message.Address)) == 0 {
-		return errors.New("address can't be empty")
+		return ErrEmptyAddress
 	}
 
-	_, err := strconv.ParseFloat(message.Value, 64)
-	if err != nil {
-		return err
+	if isZeroAddress(message.Address) {
+		return ErrZeroAddress
+	}
+
+	if len(message.Contract) != 0 && strings.EqualFold(message.Address, message.Contract) {
+		return ErrDestinationIsContract
+	}
+
+	if !transactionValueRegexp.MatchString(message.Value) {
+		return ErrMalformedValue
 	}
 
 	return nil
@@ -116,11 +366,15 @@ func ValidateReceivedAcceptRequestAddressForTransaction(message *protobuf.Accept
 	}
 
 	if len(message.Id) == 0 {
-		return errors.New("messageID can't be empty")
+		return ErrEmptyMessageID
 	}
 
 	if len(strings.TrimSpace(message.Address)) == 0 {
-		return errors.New("address can't be empty")
+		return ErrEmptyAddress
+	}
+
+	if isZeroAddress(message.Address) {
+		return ErrZeroAddress
 	}
 
 	return nil
@@ -132,7 +386,7 @@ func ValidateReceivedDeclineRequestAddressForTransaction(message *protobuf.Decli
 	}
 
 	if len(message.Id) == 0 {
-		return errors.New("messageID can't be empty")
+		return ErrEmptyMessageID
 	}
 
 	return nil
@@ -144,52 +398,431 @@ func ValidateReceivedDeclineRequestTransaction(message *protobuf.DeclineRequestT
 	}
 
 	if len(message.Id) == 0 {
-		return errors.New("messageID can't be empty")
+		return ErrEmptyMessageID
 	}
 
 	return nil
 }
 
+// ChatMessageValidationOptions enables optional, stricter checks in
+// ValidateReceivedChatMessageWithOptions. The zero value disables all of them.
+type ChatMessageValidationOptions struct {
+	// ForbidBidiOverride rejects text containing unicode bidirectional
+	// override or embedding characters, which can be used to spoof the
+	// displayed text. Right-to-left scripts are still accepted.
+	ForbidBidiOverride bool
+	// MaxMentions is the maximum number of distinct users the text can
+	// mention, 0 disables the check
+	MaxMentions int
+	// MaxRepeatedMentions is the maximum number of times the text can
+	// mention the same user, 0 disables the check
+	MaxRepeatedMentions int
+	// MaxTextLength is the maximum length of the text in runes, so that
+	// multibyte characters count the same as ascii ones, 0 disables the check
+	MaxTextLength int
+	// AllowedChatIDs restricts the chats messages are accepted for,
+	// nil disables the check
+	AllowedChatIDs map[string]bool
+	// RequireNormalizedText rejects text with leading or trailing whitespace
+	RequireNormalizedText bool
+	// ForbidLinkOnly rejects text that is empty once links are removed
+	ForbidLinkOnly bool
+	// MaxNewlines is the maximum number of newline characters in the text,
+	// 0 disables the check. DefaultMaxNewlines is a sane value.
+	MaxNewlines int
+	// MaxConsecutiveBlankLines is the maximum number of consecutive blank
+	// lines in the text, 0 disables the check.
+	// DefaultMaxConsecutiveBlankLines is a sane value.
+	MaxConsecutiveBlankLines int
+	// TextDenyList rejects text it matches, nil disables the check
+	TextDenyList TextMatcher
+}
+
+// TextMatcher matches the text of chat messages
+type TextMatcher interface {
+	Match(text string) bool
+}
+
+type keywordDenyList struct {
+	regexp *regexp.Regexp
+}
+
+// NewKeywordDenyList returns a TextMatcher matching text that contains any
+// of keywords as whole words, case-insensitively
+func NewKeywordDenyList(keywords []string) TextMatcher {
+	var quoted []string
+	for _, keyword := range keywords {
+		if len(strings.TrimSpace(keyword)) != 0 {
+			quoted = append(quoted, regexp.QuoteMeta(keyword))
+		}
+	}
+	if len(quoted) == 0 {
+		return &keywordDenyList{}
+	}
+
+	// \b only knows about ascii, so word boundaries are spelled out
+	return &keywordDenyList{
+		regexp: regexp.MustCompile(`(?i)(^|[^\p{L}\p{N}_])(` + strings.Join(quoted, "|") + `)($|[^\p{L}\p{N}_])`),
+	}
+}
+
+func (l *keywordDenyList) Match(text string) bool {
+	return l.regexp != nil && l.regexp.MatchString(text)
+}
+
+const (
+	DefaultMaxNewlines              = 200
+	DefaultMaxConsecutiveBlankLines = 5
+)
+
+// linkRegexp matches the links stripped from the text by the ForbidLinkOnly option
+var linkRegexp = regexp.MustCompile(`(?i)\b(https?://|www\.)\S+`)
+
+// TransactionResponse is a message answering a transaction command, Id is
+// the ID of the message with the original request
+type TransactionResponse interface {
+	GetId() string
+}
+
+// ValidateReceivedTransactionResponses flags responses in a batch that answer
+// a request already answered earlier in the same batch, which indicates a
+// replay. The result has an entry per response, nil for the valid ones.
+func ValidateReceivedTransactionResponses(responses []TransactionResponse) []error {
+	errs := make([]error, len(responses))
+	seen := make(map[string]bool)
+	for i, response := range responses {
+		id := response.GetId()
+		if seen[id] {
+			errs[i] = ErrDuplicateResponseID
+		}
+		seen[id] = true
+	}
+	return errs
+}
+
 func ValidateReceivedChatMessage(message *protobuf.ChatMessage, whisperTimestamp uint64) error {
+	return ValidateReceivedChatMessageWithOptions(message, whisperTimestamp, ChatMessageValidationOptions{})
+}
+
+func ValidateReceivedChatMessageWithOptions(message *protobuf.ChatMessage, whisperTimestamp uint64, options ChatMessageValidationOptions) error {
 	if err := validateClockValue(message.Clock, whisperTimestamp); err != nil {
 		return err
 	}
 
 	if message.Timestamp == 0 {
-		return errors.New("timestamp can't be 0")
+		return ErrZeroTimestamp
 	}
 
-	if len(strings.TrimSpace(message.Text)) == 0 {
-		return errors.New("text can't be empty")
+	// Stickers carry their content in the payload, the text is only an
+	// optional caption
+	if message.ContentType != protobuf.ChatMessage_STICKER && len(strings.TrimSpace(message.Text)) == 0 {
+		return ErrEmptyText
 	}
 
 	if len(message.ChatId) == 0 {
-		return errors.New("chatId can't be empty")
+		return ErrEmptyChatID
 	}
 
 	if message.ContentType == protobuf.ChatMessage_UNKNOWN_CONTENT_TYPE {
-		return errors.New("unknown content type")
+		return ErrUnknownContentType
 	}
 
 	if message.ContentType == protobuf.ChatMessage_TRANSACTION_COMMAND {
-		return errors.New("can't receive request address for transaction from others")
+		return ErrTransactionCommandMessage
 	}
 
 	if message.MessageType == protobuf.ChatMessage_UNKNOWN_MESSAGE_TYPE || message.MessageType == protobuf.ChatMessage_SYSTEM_MESSAGE_PRIVATE_GROUP {
-		return errors.New("unknown message type")
+		return ErrUnknownMessageType
 	}
 
 	if message.ContentType == protobuf.ChatMessage_STICKER {
 		if message.Payload == nil {
-			return errors.New("no sticker content")
+			return ErrNoStickerContent
 		}
 		sticker := message.GetSticker()
 		if sticker == nil {
-			return errors.New("no sticker content")
+			return ErrNoStickerContent
 		}
 		if len(sticker.Hash) == 0 {
-			return errors.New("sticker hash not set")
+			return ErrNoStickerHash
+		}
+	} else if message.Payload != nil {
+		// The payload is a oneof, so only one can be set at a time, but it
+		// still has to be the one of the declared content type
+		return ErrUnexpectedPayload
+	}
+
+	if options.AllowedChatIDs != nil && !options.AllowedChatIDs[message.ChatId] {
+		return ErrChatNotAllowed
+	}
+
+	if options.RequireNormalizedText && message.Text != strings.TrimSpace(message.Text) {
+		return ErrUnnormalizedText
+	}
+
+	// Stickers can have no caption, which is not a link only message
+	if options.ForbidLinkOnly && len(message.Text) != 0 && len(strings.TrimSpace(linkRegexp.ReplaceAllString(message.Text, ""))) == 0 {
+		return ErrLinkOnlyMessage
+	}
+
+	if options.MaxNewlines > 0 && strings.Count(message.Text, "\n") > options.MaxNewlines {
+		return ErrTooManyLines
+	}
+
+	if options.MaxConsecutiveBlankLines > 0 && maxConsecutiveBlankLines(message.Text) > options.MaxConsecutiveBlankLines {
+		return ErrTooManyLines
+	}
+
+	if options.TextDenyList != nil && options.TextDenyList.Match(message.Text) {
+		return ErrBannedKeyword
+	}
+
+	if options.ForbidBidiOverride && strings.IndexFunc(message.Text, isBidiOverride) != -1 {
+		return ErrBidiOverride
+	}
+
+	if options.MaxTextLength > 0 && utf8.RuneCountInString(message.Text) > options.MaxTextLength {
+		return ErrTextTooLong
+	}
+
+	if options.MaxMentions > 0 || options.MaxRepeatedMentions > 0 {
+		if err := validateMentions(message.Text, options.MaxMentions, options.MaxRepeatedMentions); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateMentions checks the mentions parsed from text, counted per
+// mentioned public key
+func validateMentions(text string, maxMentions int, maxRepeatedMentions int) error {
+	mentions := make(map[string]int)
+	ast.WalkFunc(markdown.Parse([]byte(text), nil), func(node ast.Node, entering bool) ast.WalkStatus {
+		if mention, ok := node.(*ast.Mention); ok && entering {
+			mentions[string(mention.Literal)]++
+		}
+		return ast.GoToNext
+	})
+
+	if maxMentions > 0 && len(mentions) > maxMentions {
+		return ErrTooManyMentions
+	}
+
+	if maxRepeatedMentions > 0 {
+		for _, count := range mentions {
+			if count > maxRepeatedMentions {
+				return ErrTooManyMentions
+			}
+		}
+	}
+
+	return nil
+}
+
+// maxConsecutiveBlankLines returns the longest run of blank lines in text
+func maxConsecutiveBlankLines(text string) int {
+	var max, current int
+	for _, line := range strings.Split(text, "\n") {
+		if len(strings.TrimSpace(line)) != 0 {
+			current = 0
+			continue
+		}
+		current++
+		if current > max {
+			max = current
 		}
 	}
+	return max
+}
+
+// isBidiOverride returns whether r is one of the unicode bidirectional
+// embedding, override or isolate control characters
+func isBidiOverride(r rune) bool {
+	return (r >= '\u202A' && r <= '\u202E') || (r >= '\u2066' && r <= '\u2069')
+}
+
+// ValidateReceivedChatMessageID runs the checks that need the message ID.
+// The ID is derived from the envelope once it has been hashed, so this can't
+// be part of ValidateReceivedChatMessage and is called separately.
+func ValidateReceivedChatMessageID(message *protobuf.ChatMessage, messageID string) error {
+	if len(message.ResponseTo) != 0 && message.ResponseTo == messageID {
+		return ErrSelfReply
+	}
+
 	return nil
 }
+
+// ValidateReceived validates a received application message using the
+// validator for its type
+func ValidateReceived(message proto.Message, whisperTimestamp uint64) error {
+	return ValidateReceivedWithTimingHook(message, whisperTimestamp, nil)
+}
+
+// ValidateReceivedWithTimingHook is ValidateReceived, reporting how long the
+// validation took to hook if it's not nil
+func ValidateReceivedWithTimingHook(message proto.Message, whisperTimestamp uint64, hook ValidationTimingHook) error {
+	messageType, validator := receivedMessageValidator(message, whisperTimestamp)
+	if validator == nil {
+		return ErrUnsupportedMessageType
+	}
+
+	return validateWithTimingHook(hook, messageType, validator)
+}
+
+// receivedMessageValidator returns the type of message and its validator,
+// which is nil if the type is not supported
+func receivedMessageValidator(message proto.Message, whisperTimestamp uint64) (protobuf.ApplicationMetadataMessage_Type, func() error) {
+	switch m := message.(type) {
+	case *protobuf.ChatMessage:
+		return protobuf.ApplicationMetadataMessage_CHAT_MESSAGE, func() error {
+			return ValidateReceivedChatMessage(m, whisperTimestamp)
+		}
+	case *protobuf.MembershipUpdateMessage:
+		return protobuf.ApplicationMetadataMessage_MEMBERSHIP_UPDATE_MESSAGE, func() error {
+			// Check the size before decoding, as each event signature is verified
+			if err := validateMembershipUpdateEventsCount(len(m.Events)); err != nil {
+				return err
+			}
+			decoded, err := protocol.MembershipUpdateMessageFromProtobuf(m)
+			if err != nil {
+				return err
+			}
+			return ValidateMembershipUpdateMessage(decoded, whisperTimestamp)
+		}
+	case *protobuf.PairInstallation:
+		return protobuf.ApplicationMetadataMessage_PAIR_INSTALLATION, func() error {
+			return ValidateReceivedPairInstallation(m, whisperTimestamp)
+		}
+	case *protobuf.SyncInstallationContact:
+		return protobuf.ApplicationMetadataMessage_SYNC_INSTALLATION_CONTACT, func() error {
+			return ValidateReceivedSyncInstallationContact(m, whisperTimestamp)
+		}
+	case *protobuf.RequestAddressForTransaction:
+		return protobuf.ApplicationMetadataMessage_REQUEST_ADDRESS_FOR_TRANSACTION, func() error {
+			return ValidateReceivedRequestAddressForTransaction(m, whisperTimestamp)
+		}
+	case *protobuf.RequestTransaction:
+		return protobuf.ApplicationMetadataMessage_REQUEST_TRANSACTION, func() error {
+			return ValidateReceivedRequestTransaction(m, whisperTimestamp)
+		}
+	case *protobuf.AcceptRequestAddressForTransaction:
+		return protobuf.ApplicationMetadataMessage_ACCEPT_REQUEST_ADDRESS_FOR_TRANSACTION, func() error {
+			return ValidateReceivedAcceptRequestAddressForTransaction(m, whisperTimestamp)
+		}
+	case *protobuf.DeclineRequestAddressForTransaction:
+		return protobuf.ApplicationMetadataMessage_DECLINE_REQUEST_ADDRESS_FOR_TRANSACTION, func() error {
+			return ValidateReceivedDeclineRequestAddressForTransaction(m, whisperTimestamp)
+		}
+	case *protobuf.DeclineRequestTransaction:
+		return protobuf.ApplicationMetadataMessage_DECLINE_REQUEST_TRANSACTION, func() error {
+			return ValidateReceivedDeclineRequestTransaction(m, whisperTimestamp)
+		}
+	case *protobuf.SendTransaction:
+		return protobuf.ApplicationMetadataMessage_SEND_TRANSACTION, func() error {
+			return ValidateReceivedSendTransaction(m, whisperTimestamp)
+		}
+	default:
+		return protobuf.ApplicationMetadataMessage_UNKNOWN, nil
+	}
+}
+
+// validationErrorSentinels are the reasons messages are rejected for in a
+// BatchValidationSummary, new validation errors need to be added here
+var validationErrorSentinels = []error{
+	ErrZeroClock,
+	ErrClockTooHigh,
+	ErrZeroTimestamp,
+	ErrEmptyText,
+	ErrEmptyChatID,
+	ErrUnknownContentType,
+	ErrUnknownMessageType,
+	ErrNoStickerContent,
+	ErrNoStickerHash,
+	ErrTransactionCommandMessage,
+	ErrSelfReply,
+	ErrUnexpectedPayload,
+	ErrBidiOverride,
+	ErrTooManyMentions,
+	ErrTextTooLong,
+	ErrChatNotAllowed,
+	ErrUnnormalizedText,
+	ErrLinkOnlyMessage,
+	ErrTooManyLines,
+	ErrBannedKeyword,
+	ErrTooManyMembershipEvents,
+	ErrDuplicateMembershipEvent,
+	ErrEmptyMembershipUpdate,
+	ErrClockTooSmall,
+	ErrInvalidPublicKey,
+	ErrEmptyValue,
+	ErrMalformedValue,
+	ErrEmptyAddress,
+	ErrEmptyMessageID,
+	ErrEmptyTransactionHash,
+	ErrNilSignature,
+	ErrZeroAddress,
+	ErrDestinationIsContract,
+	ErrEmptyName,
+	ErrEmptyDeviceType,
+	ErrEmptyInstallationID,
+	ErrInvalidInstallationID,
+	ErrInvalidDeviceName,
+	ErrNonMonotonicPairingClock,
+	ErrInvalidSignatureLength,
+	ErrInvalidSignatureRecoveryID,
+	ErrDuplicateResponseID,
+	ErrUnsupportedMessageType,
+}
+
+// rejectionReason returns the sentinel err matches, or
+// ErrOtherValidationFailure. Errors are matched with errors.Is as they can be
+// wrapped, aggregated errors are counted by their first error.
+func rejectionReason(err error) error {
+	var aggregate ValidationErrors
+	if errors.As(err, &aggregate) && len(aggregate) != 0 {
+		err = aggregate[0]
+	}
+
+	for _, sentinel := range validationErrorSentinels {
+		if errors.Is(err, sentinel) {
+			return sentinel
+		}
+	}
+	return ErrOtherValidationFailure
+}
+
+// BatchValidationSummary is the result of the validation of a batch of messages
+type BatchValidationSummary struct {
+	// Results has the validation error of each message, nil for valid ones
+	Results []error
+	// Valid is the number of valid messages
+	Valid int
+	// Rejected is the number of rejected messages
+	Rejected int
+	// RejectedByReason counts the rejected messages by validation error
+	// sentinel, ErrOtherValidationFailure counts the ones not matching any
+	RejectedByReason map[error]int
+}
+
+// ValidateBatchSummary validates each message with ValidateReceived and
+// aggregates the results
+func ValidateBatchSummary(messages []proto.Message, whisperTimestamp uint64) BatchValidationSummary {
+	summary := BatchValidationSummary{
+		Results:          make([]error, len(messages)),
+		RejectedByReason: make(map[error]int),
+	}
+
+	for i, message := range messages {
+		err := ValidateReceived(message, whisperTimestamp)
+		summary.Results[i] = err
+		if err != nil {
+			summary.Rejected++
+			summary.RejectedByReason[rejectionReason(err)]++
+		} else {
+			summary.Valid++
+		}
+	}
+
+	return summary
+}
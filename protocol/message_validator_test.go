@@ -1,11 +1,25 @@
 package protocol
 
 import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/status-im/status-go/eth-node/crypto"
+	"github.com/status-im/status-go/eth-node/types"
 	"github.com/status-im/status-go/protocol/protobuf"
+	v1protocol "github.com/status-im/status-go/protocol/v1"
+)
+
+const (
+	testPublicKey1 = "0x0424a68f89ba5fcd5e0640c1e1f591d561fa4125ca4e2a43592bc4123eca10ce064e522c254bb83079ba404327f6eafc01ec90a1444331fe769d3f3a7f90b0dde1"
+	testPublicKey2 = "0x04aebe2bb01a988abe7d978662f21de7760486119876c680e5a559e38e086a2df6dad41c4e4d9079c03db3bced6cb70fca76afc5650e50ea19b81572046a813534"
 )
 
 type MessageValidatorSuite struct {
@@ -305,6 +319,40 @@ func (s *MessageValidatorSuite) TestValidatePlainTextMessage() {
 				ContentType: protobuf.ChatMessage_STICKER,
 			},
 		},
+		{
+			Name:             "Valid sticker message without caption",
+			WhisperTimestamp: 2,
+			Valid:            true,
+			Message: protobuf.ChatMessage{
+				ChatId:     "a",
+				Clock:      2,
+				Timestamp:  3,
+				ResponseTo: "",
+				EnsName:    "",
+				Payload: &protobuf.ChatMessage_Sticker{
+					Sticker: &protobuf.StickerMessage{
+						Pack: 1,
+						Hash: "some-hash",
+					},
+				},
+				MessageType: protobuf.ChatMessage_ONE_TO_ONE,
+				ContentType: protobuf.ChatMessage_STICKER,
+			},
+		},
+		{
+			Name:             "Invalid sticker message without caption and content",
+			WhisperTimestamp: 2,
+			Valid:            false,
+			Message: protobuf.ChatMessage{
+				ChatId:      "a",
+				Clock:       2,
+				Timestamp:   3,
+				ResponseTo:  "",
+				EnsName:     "",
+				MessageType: protobuf.ChatMessage_ONE_TO_ONE,
+				ContentType: protobuf.ChatMessage_STICKER,
+			},
+		},
 		{
 			Name:             "Invalid sticker message without Hash",
 			WhisperTimestamp: 2,
@@ -325,6 +373,27 @@ func (s *MessageValidatorSuite) TestValidatePlainTextMessage() {
 				ContentType: protobuf.ChatMessage_STICKER,
 			},
 		},
+		{
+			Name:             "Invalid text message with a sticker payload",
+			WhisperTimestamp: 2,
+			Valid:            false,
+			Message: protobuf.ChatMessage{
+				ChatId:     "a",
+				Text:       "valid",
+				Clock:      2,
+				Timestamp:  3,
+				ResponseTo: "",
+				EnsName:    "",
+				Payload: &protobuf.ChatMessage_Sticker{
+					Sticker: &protobuf.StickerMessage{
+						Pack: 1,
+						Hash: "some-hash",
+					},
+				},
+				MessageType: protobuf.ChatMessage_ONE_TO_ONE,
+				ContentType: protobuf.ChatMessage_TEXT_PLAIN,
+			},
+		},
 		{
 			Name:             "Invalid sticker message without any content",
 			WhisperTimestamp: 2,
@@ -353,3 +422,1088 @@ func (s *MessageValidatorSuite) TestValidatePlainTextMessage() {
 		})
 	}
 }
+
+func (s *MessageValidatorSuite) TestValidationErrors() {
+	var err error = ValidationErrors{ErrEmptyText, ErrEmptyChatID, ErrUnknownMessageType}
+
+	s.True(errors.Is(err, ErrEmptyText))
+	s.True(errors.Is(err, ErrEmptyChatID))
+	s.True(errors.Is(err, ErrUnknownMessageType))
+	s.False(errors.Is(err, ErrUnknownContentType))
+
+	var validationErrors ValidationErrors
+	s.True(errors.As(err, &validationErrors))
+	s.Len(validationErrors, 3)
+}
+
+func (s *MessageValidatorSuite) TestValidateChatMessageID() {
+	testCases := []struct {
+		Name      string
+		MessageID string
+		Valid     bool
+		Message   protobuf.ChatMessage
+	}{
+		{
+			Name:      "Not a reply",
+			MessageID: "0x01",
+			Valid:     true,
+			Message:   protobuf.ChatMessage{},
+		},
+		{
+			Name:      "Reply to another message",
+			MessageID: "0x01",
+			Valid:     true,
+			Message: protobuf.ChatMessage{
+				ResponseTo: "0x02",
+			},
+		},
+		{
+			Name:      "Reply to itself",
+			MessageID: "0x01",
+			Valid:     false,
+			Message: protobuf.ChatMessage{
+				ResponseTo: "0x01",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		s.Run(tc.Name, func() {
+			err := ValidateReceivedChatMessageID(&tc.Message, tc.MessageID)
+			if tc.Valid {
+				s.Nil(err)
+			} else {
+				s.Equal(ErrSelfReply, err)
+			}
+		})
+	}
+}
+
+func (s *MessageValidatorSuite) TestValidateMembershipUpdateMessageEventsCount() {
+	events := make([]v1protocol.MembershipUpdateEvent, MaxMembershipUpdateEvents+1)
+	for i := range events {
+		events[i] = v1protocol.NewMemberJoinedEvent(30)
+		events[i].Signature = []byte(strconv.Itoa(i))
+	}
+
+	message := &v1protocol.MembershipUpdateMessage{Events: events[:MaxMembershipUpdateEvents]}
+	s.Nil(ValidateMembershipUpdateMessage(message, 30))
+
+	message = &v1protocol.MembershipUpdateMessage{Events: events}
+	s.Equal(ErrTooManyMembershipEvents, ValidateMembershipUpdateMessage(message, 30))
+}
+
+func (s *MessageValidatorSuite) TestValidatePairInstallation() {
+	testCases := []struct {
+		Name             string
+		WhisperTimestamp uint64
+		Valid            bool
+		Message          protobuf.PairInstallation
+	}{
+		{
+			Name:             "valid message",
+			WhisperTimestamp: MinPlausibleClockValue,
+			Valid:            true,
+			Message: protobuf.PairInstallation{
+				Clock:          MinPlausibleClockValue,
+				InstallationId: "6b3a7d1e-5f0c-4d9e-9c6b-2a8f4e1d7c35",
+				DeviceType:     "ios",
+				Name:           "my-device",
+			},
+		},
+		{
+			Name:             "clock value too small",
+			WhisperTimestamp: MinPlausibleClockValue,
+			Valid:            false,
+			Message: protobuf.PairInstallation{
+				Clock:          MinPlausibleClockValue - 1,
+				InstallationId: "6b3a7d1e-5f0c-4d9e-9c6b-2a8f4e1d7c35",
+				DeviceType:     "ios",
+				Name:           "my-device",
+			},
+		},
+		{
+			Name:             "installation id is a v5 uuid",
+			WhisperTimestamp: MinPlausibleClockValue,
+			Valid:            true,
+			Message: protobuf.PairInstallation{
+				Clock:          MinPlausibleClockValue,
+				InstallationId: "cf40e6c9-262f-5a76-9621-7b6fe0a91cd2",
+				DeviceType:     "ios",
+				Name:           "my-device",
+			},
+		},
+		{
+			Name:             "installation id not a uuid",
+			WhisperTimestamp: MinPlausibleClockValue,
+			Valid:            false,
+			Message: protobuf.PairInstallation{
+				Clock:          MinPlausibleClockValue,
+				InstallationId: "some-installation-id",
+				DeviceType:     "ios",
+				Name:           "my-device",
+			},
+		},
+		{
+			Name:             "valid non ascii name",
+			WhisperTimestamp: MinPlausibleClockValue,
+			Valid:            true,
+			Message: protobuf.PairInstallation{
+				Clock:          MinPlausibleClockValue,
+				InstallationId: "6b3a7d1e-5f0c-4d9e-9c6b-2a8f4e1d7c35",
+				DeviceType:     "ios",
+				Name:           "Téléphone de André",
+			},
+		},
+		{
+			Name:             "name with zero width joiner emoji sequence",
+			WhisperTimestamp: MinPlausibleClockValue,
+			Valid:            true,
+			Message: protobuf.PairInstallation{
+				Clock:          MinPlausibleClockValue,
+				InstallationId: "6b3a7d1e-5f0c-4d9e-9c6b-2a8f4e1d7c35",
+				DeviceType:     "ios",
+				Name:           "\U0001f469\u200d\U0001f4bb laptop",
+			},
+		},
+		{
+			Name:             "name with no-break space",
+			WhisperTimestamp: MinPlausibleClockValue,
+			Valid:            true,
+			Message: protobuf.PairInstallation{
+				Clock:          MinPlausibleClockValue,
+				InstallationId: "6b3a7d1e-5f0c-4d9e-9c6b-2a8f4e1d7c35",
+				DeviceType:     "ios",
+				Name:           "my\u00a0device",
+			},
+		},
+		{
+			Name:             "name with bidi override",
+			WhisperTimestamp: MinPlausibleClockValue,
+			Valid:            false,
+			Message: protobuf.PairInstallation{
+				Clock:          MinPlausibleClockValue,
+				InstallationId: "6b3a7d1e-5f0c-4d9e-9c6b-2a8f4e1d7c35",
+				DeviceType:     "ios",
+				Name:           "my\u202edevice",
+			},
+		},
+		{
+			Name:             "name with control characters",
+			WhisperTimestamp: MinPlausibleClockValue,
+			Valid:            false,
+			Message: protobuf.PairInstallation{
+				Clock:          MinPlausibleClockValue,
+				InstallationId: "6b3a7d1e-5f0c-4d9e-9c6b-2a8f4e1d7c35",
+				DeviceType:     "ios",
+				Name:           "my\x1b[2Jdevice\x00",
+			},
+		},
+		{
+			Name:             "name too long",
+			WhisperTimestamp: MinPlausibleClockValue,
+			Valid:            false,
+			Message: protobuf.PairInstallation{
+				Clock:          MinPlausibleClockValue,
+				InstallationId: "6b3a7d1e-5f0c-4d9e-9c6b-2a8f4e1d7c35",
+				DeviceType:     "ios",
+				Name:           strings.Repeat("a", maxDeviceNameLength+1),
+			},
+		},
+		{
+			Name:             "missing name",
+			WhisperTimestamp: MinPlausibleClockValue,
+			Valid:            false,
+			Message: protobuf.PairInstallation{
+				Clock:          MinPlausibleClockValue,
+				InstallationId: "6b3a7d1e-5f0c-4d9e-9c6b-2a8f4e1d7c35",
+				DeviceType:     "ios",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		s.Run(tc.Name, func() {
+			err := ValidateReceivedPairInstallation(&tc.Message, tc.WhisperTimestamp)
+			if tc.Valid {
+				s.Nil(err)
+			} else {
+				s.NotNil(err)
+			}
+		})
+	}
+}
+
+func (s *MessageValidatorSuite) TestValidateChatMessageWithOptions() {
+	testCases := []struct {
+		Name             string
+		WhisperTimestamp uint64
+		Options          ChatMessageValidationOptions
+		Valid            bool
+		Message          protobuf.ChatMessage
+	}{
+		{
+			Name:             "Valid right-to-left message",
+			WhisperTimestamp: 2,
+			Options:          ChatMessageValidationOptions{ForbidBidiOverride: true},
+			Valid:            true,
+			Message: protobuf.ChatMessage{
+				ChatId:      "a",
+				Text:        "مرحبا بالعالم",
+				Clock:       2,
+				Timestamp:   3,
+				MessageType: protobuf.ChatMessage_ONE_TO_ONE,
+				ContentType: protobuf.ChatMessage_TEXT_PLAIN,
+			},
+		},
+		{
+			Name:             "Bidi override allowed by default",
+			WhisperTimestamp: 2,
+			Valid:            true,
+			Message: protobuf.ChatMessage{
+				ChatId:      "a",
+				Text:        "invoice-\u202efdp.exe",
+				Clock:       2,
+				Timestamp:   3,
+				MessageType: protobuf.ChatMessage_ONE_TO_ONE,
+				ContentType: protobuf.ChatMessage_TEXT_PLAIN,
+			},
+		},
+		{
+			Name:             "Invalid bidi override",
+			WhisperTimestamp: 2,
+			Options:          ChatMessageValidationOptions{ForbidBidiOverride: true},
+			Valid:            false,
+			Message: protobuf.ChatMessage{
+				ChatId:      "a",
+				Text:        "invoice-\u202efdp.exe",
+				Clock:       2,
+				Timestamp:   3,
+				MessageType: protobuf.ChatMessage_ONE_TO_ONE,
+				ContentType: protobuf.ChatMessage_TEXT_PLAIN,
+			},
+		},
+		{
+			Name:             "Valid mentions",
+			WhisperTimestamp: 2,
+			Options:          ChatMessageValidationOptions{MaxMentions: 2, MaxRepeatedMentions: 2},
+			Valid:            true,
+			Message: protobuf.ChatMessage{
+				ChatId:      "a",
+				Text:        "hey @" + testPublicKey1 + " and @" + testPublicKey2 + ", how are you @" + testPublicKey1,
+				Clock:       2,
+				Timestamp:   3,
+				MessageType: protobuf.ChatMessage_ONE_TO_ONE,
+				ContentType: protobuf.ChatMessage_TEXT_PLAIN,
+			},
+		},
+		{
+			Name:             "Too many distinct mentions",
+			WhisperTimestamp: 2,
+			Options:          ChatMessageValidationOptions{MaxMentions: 1},
+			Valid:            false,
+			Message: protobuf.ChatMessage{
+				ChatId:      "a",
+				Text:        "hey @" + testPublicKey1 + " and @" + testPublicKey2,
+				Clock:       2,
+				Timestamp:   3,
+				MessageType: protobuf.ChatMessage_ONE_TO_ONE,
+				ContentType: protobuf.ChatMessage_TEXT_PLAIN,
+			},
+		},
+		{
+			Name:             "Flood of repeated mentions",
+			WhisperTimestamp: 2,
+			Options:          ChatMessageValidationOptions{MaxRepeatedMentions: 2},
+			Valid:            false,
+			Message: protobuf.ChatMessage{
+				ChatId:      "a",
+				Text:        strings.Repeat("@"+testPublicKey1+" ", 10),
+				Clock:       2,
+				Timestamp:   3,
+				MessageType: protobuf.ChatMessage_ONE_TO_ONE,
+				ContentType: protobuf.ChatMessage_TEXT_PLAIN,
+			},
+		},
+		{
+			Name:             "Ascii text at max length",
+			WhisperTimestamp: 2,
+			Options:          ChatMessageValidationOptions{MaxTextLength: 5},
+			Valid:            true,
+			Message: protobuf.ChatMessage{
+				ChatId:      "a",
+				Text:        "hello",
+				Clock:       2,
+				Timestamp:   3,
+				MessageType: protobuf.ChatMessage_ONE_TO_ONE,
+				ContentType: protobuf.ChatMessage_TEXT_PLAIN,
+			},
+		},
+		{
+			Name:             "Emoji text at max length",
+			WhisperTimestamp: 2,
+			Options:          ChatMessageValidationOptions{MaxTextLength: 5},
+			Valid:            true,
+			Message: protobuf.ChatMessage{
+				ChatId:      "a",
+				Text:        "😀😀😀😀😀",
+				Clock:       2,
+				Timestamp:   3,
+				MessageType: protobuf.ChatMessage_ONE_TO_ONE,
+				ContentType: protobuf.ChatMessage_TEXT_PLAIN,
+			},
+		},
+		{
+			Name:             "Ascii text over max length",
+			WhisperTimestamp: 2,
+			Options:          ChatMessageValidationOptions{MaxTextLength: 5},
+			Valid:            false,
+			Message: protobuf.ChatMessage{
+				ChatId:      "a",
+				Text:        "hello!",
+				Clock:       2,
+				Timestamp:   3,
+				MessageType: protobuf.ChatMessage_ONE_TO_ONE,
+				ContentType: protobuf.ChatMessage_TEXT_PLAIN,
+			},
+		},
+		{
+			Name:             "Emoji text over max length",
+			WhisperTimestamp: 2,
+			Options:          ChatMessageValidationOptions{MaxTextLength: 5},
+			Valid:            false,
+			Message: protobuf.ChatMessage{
+				ChatId:      "a",
+				Text:        "😀😀😀😀😀😀",
+				Clock:       2,
+				Timestamp:   3,
+				MessageType: protobuf.ChatMessage_ONE_TO_ONE,
+				ContentType: protobuf.ChatMessage_TEXT_PLAIN,
+			},
+		},
+		{
+			Name:             "Allowed chat",
+			WhisperTimestamp: 2,
+			Options:          ChatMessageValidationOptions{AllowedChatIDs: map[string]bool{"status": true}},
+			Valid:            true,
+			Message: protobuf.ChatMessage{
+				ChatId:      "status",
+				Text:        "some-text",
+				Clock:       2,
+				Timestamp:   3,
+				MessageType: protobuf.ChatMessage_ONE_TO_ONE,
+				ContentType: protobuf.ChatMessage_TEXT_PLAIN,
+			},
+		},
+		{
+			Name:             "Chat not allowed",
+			WhisperTimestamp: 2,
+			Options:          ChatMessageValidationOptions{AllowedChatIDs: map[string]bool{"status": true}},
+			Valid:            false,
+			Message: protobuf.ChatMessage{
+				ChatId:      "spam",
+				Text:        "some-text",
+				Clock:       2,
+				Timestamp:   3,
+				MessageType: protobuf.ChatMessage_ONE_TO_ONE,
+				ContentType: protobuf.ChatMessage_TEXT_PLAIN,
+			},
+		},
+		{
+			Name:             "Normalized text",
+			WhisperTimestamp: 2,
+			Options:          ChatMessageValidationOptions{RequireNormalizedText: true},
+			Valid:            true,
+			Message: protobuf.ChatMessage{
+				ChatId:      "a",
+				Text:        "some text",
+				Clock:       2,
+				Timestamp:   3,
+				MessageType: protobuf.ChatMessage_ONE_TO_ONE,
+				ContentType: protobuf.ChatMessage_TEXT_PLAIN,
+			},
+		},
+		{
+			Name:             "Padded text allowed by default",
+			WhisperTimestamp: 2,
+			Valid:            true,
+			Message: protobuf.ChatMessage{
+				ChatId:      "a",
+				Text:        "  some text\n",
+				Clock:       2,
+				Timestamp:   3,
+				MessageType: protobuf.ChatMessage_ONE_TO_ONE,
+				ContentType: protobuf.ChatMessage_TEXT_PLAIN,
+			},
+		},
+		{
+			Name:             "Padded text",
+			WhisperTimestamp: 2,
+			Options:          ChatMessageValidationOptions{RequireNormalizedText: true},
+			Valid:            false,
+			Message: protobuf.ChatMessage{
+				ChatId:      "a",
+				Text:        "  some text\n",
+				Clock:       2,
+				Timestamp:   3,
+				MessageType: protobuf.ChatMessage_ONE_TO_ONE,
+				ContentType: protobuf.ChatMessage_TEXT_PLAIN,
+			},
+		},
+		{
+			Name:             "Link with text",
+			WhisperTimestamp: 2,
+			Options:          ChatMessageValidationOptions{ForbidLinkOnly: true},
+			Valid:            true,
+			Message: protobuf.ChatMessage{
+				ChatId:      "a",
+				Text:        "have a look at https://status.im",
+				Clock:       2,
+				Timestamp:   3,
+				MessageType: protobuf.ChatMessage_ONE_TO_ONE,
+				ContentType: protobuf.ChatMessage_TEXT_PLAIN,
+			},
+		},
+		{
+			Name:             "Link only allowed by default",
+			WhisperTimestamp: 2,
+			Valid:            true,
+			Message: protobuf.ChatMessage{
+				ChatId:      "a",
+				Text:        "https://status.im www.example.com",
+				Clock:       2,
+				Timestamp:   3,
+				MessageType: protobuf.ChatMessage_ONE_TO_ONE,
+				ContentType: protobuf.ChatMessage_TEXT_PLAIN,
+			},
+		},
+		{
+			Name:             "Link only",
+			WhisperTimestamp: 2,
+			Options:          ChatMessageValidationOptions{ForbidLinkOnly: true},
+			Valid:            false,
+			Message: protobuf.ChatMessage{
+				ChatId:      "a",
+				Text:        "https://status.im\n www.example.com/path?q=1",
+				Clock:       2,
+				Timestamp:   3,
+				MessageType: protobuf.ChatMessage_ONE_TO_ONE,
+				ContentType: protobuf.ChatMessage_TEXT_PLAIN,
+			},
+		},
+		{
+			Name:             "Sticker without caption with link only forbidden",
+			WhisperTimestamp: 2,
+			Options:          ChatMessageValidationOptions{ForbidLinkOnly: true},
+			Valid:            true,
+			Message: protobuf.ChatMessage{
+				ChatId:    "a",
+				Clock:     2,
+				Timestamp: 3,
+				Payload: &protobuf.ChatMessage_Sticker{
+					Sticker: &protobuf.StickerMessage{
+						Pack: 1,
+						Hash: "some-hash",
+					},
+				},
+				MessageType: protobuf.ChatMessage_ONE_TO_ONE,
+				ContentType: protobuf.ChatMessage_STICKER,
+			},
+		},
+		{
+			Name:             "Sticker with link only caption",
+			WhisperTimestamp: 2,
+			Options:          ChatMessageValidationOptions{ForbidLinkOnly: true},
+			Valid:            false,
+			Message: protobuf.ChatMessage{
+				ChatId:    "a",
+				Text:      "https://status.im",
+				Clock:     2,
+				Timestamp: 3,
+				Payload: &protobuf.ChatMessage_Sticker{
+					Sticker: &protobuf.StickerMessage{
+						Pack: 1,
+						Hash: "some-hash",
+					},
+				},
+				MessageType: protobuf.ChatMessage_ONE_TO_ONE,
+				ContentType: protobuf.ChatMessage_STICKER,
+			},
+		},
+		{
+			Name:             "Multiline text",
+			WhisperTimestamp: 2,
+			Options:          ChatMessageValidationOptions{MaxNewlines: DefaultMaxNewlines, MaxConsecutiveBlankLines: DefaultMaxConsecutiveBlankLines},
+			Valid:            true,
+			Message: protobuf.ChatMessage{
+				ChatId:      "a",
+				Text:        "first line\nsecond line\n\nthird line",
+				Clock:       2,
+				Timestamp:   3,
+				MessageType: protobuf.ChatMessage_ONE_TO_ONE,
+				ContentType: protobuf.ChatMessage_TEXT_PLAIN,
+			},
+		},
+		{
+			Name:             "Newline flood",
+			WhisperTimestamp: 2,
+			Options:          ChatMessageValidationOptions{MaxNewlines: DefaultMaxNewlines},
+			Valid:            false,
+			Message: protobuf.ChatMessage{
+				ChatId:      "a",
+				Text:        "a" + strings.Repeat("\n", DefaultMaxNewlines+1) + "b",
+				Clock:       2,
+				Timestamp:   3,
+				MessageType: protobuf.ChatMessage_ONE_TO_ONE,
+				ContentType: protobuf.ChatMessage_TEXT_PLAIN,
+			},
+		},
+		{
+			Name:             "Blank lines flood",
+			WhisperTimestamp: 2,
+			Options:          ChatMessageValidationOptions{MaxConsecutiveBlankLines: DefaultMaxConsecutiveBlankLines},
+			Valid:            false,
+			Message: protobuf.ChatMessage{
+				ChatId:      "a",
+				Text:        "a" + strings.Repeat("\n \t", DefaultMaxConsecutiveBlankLines+1) + "\nb",
+				Clock:       2,
+				Timestamp:   3,
+				MessageType: protobuf.ChatMessage_ONE_TO_ONE,
+				ContentType: protobuf.ChatMessage_TEXT_PLAIN,
+			},
+		},
+		{
+			Name:             "Clean text",
+			WhisperTimestamp: 2,
+			Options:          ChatMessageValidationOptions{TextDenyList: NewKeywordDenyList([]string{"scam", "free tokens"})},
+			Valid:            true,
+			Message: protobuf.ChatMessage{
+				ChatId:      "a",
+				Text:        "this scampi is great",
+				Clock:       2,
+				Timestamp:   3,
+				MessageType: protobuf.ChatMessage_ONE_TO_ONE,
+				ContentType: protobuf.ChatMessage_TEXT_PLAIN,
+			},
+		},
+		{
+			Name:             "Banned keyword",
+			WhisperTimestamp: 2,
+			Options:          ChatMessageValidationOptions{TextDenyList: NewKeywordDenyList([]string{"scam", "free tokens"})},
+			Valid:            false,
+			Message: protobuf.ChatMessage{
+				ChatId:      "a",
+				Text:        "not a SCAM, I promise",
+				Clock:       2,
+				Timestamp:   3,
+				MessageType: protobuf.ChatMessage_ONE_TO_ONE,
+				ContentType: protobuf.ChatMessage_TEXT_PLAIN,
+			},
+		},
+		{
+			Name:             "Banned phrase",
+			WhisperTimestamp: 2,
+			Options:          ChatMessageValidationOptions{TextDenyList: NewKeywordDenyList([]string{"scam", "free tokens"})},
+			Valid:            false,
+			Message: protobuf.ChatMessage{
+				ChatId:      "a",
+				Text:        "get your Free Tokens!",
+				Clock:       2,
+				Timestamp:   3,
+				MessageType: protobuf.ChatMessage_ONE_TO_ONE,
+				ContentType: protobuf.ChatMessage_TEXT_PLAIN,
+			},
+		},
+		{
+			Name:             "Empty deny list",
+			WhisperTimestamp: 2,
+			Options:          ChatMessageValidationOptions{TextDenyList: NewKeywordDenyList(nil)},
+			Valid:            true,
+			Message: protobuf.ChatMessage{
+				ChatId:      "a",
+				Text:        "anything goes",
+				Clock:       2,
+				Timestamp:   3,
+				MessageType: protobuf.ChatMessage_ONE_TO_ONE,
+				ContentType: protobuf.ChatMessage_TEXT_PLAIN,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		s.Run(tc.Name, func() {
+			err := ValidateReceivedChatMessageWithOptions(&tc.Message, tc.WhisperTimestamp, tc.Options)
+			if tc.Valid {
+				s.Nil(err)
+			} else {
+				s.NotNil(err)
+			}
+		})
+	}
+}
+
+func (s *MessageValidatorSuite) TestValidateRequestTransaction() {
+	testCases := []struct {
+		Name             string
+		WhisperTimestamp uint64
+		Valid            bool
+		Message          protobuf.RequestTransaction
+	}{
+		{
+			Name:             "valid integer value",
+			WhisperTimestamp: 30,
+			Valid:            true,
+			Message: protobuf.RequestTransaction{
+				Clock:    30,
+				Value:    "1000",
+				Address:  "0x5ffa75ce51c3a7ebe23bde37b5e3a0143dfbcee0",
+				Contract: "0x744d70fdbe2ba4cf95131626614a1763df805b9e",
+			},
+		},
+		{
+			Name:             "valid decimal value",
+			WhisperTimestamp: 30,
+			Valid:            true,
+			Message: protobuf.RequestTransaction{
+				Clock:    30,
+				Value:    "1.5",
+				Address:  "0x5ffa75ce51c3a7ebe23bde37b5e3a0143dfbcee0",
+				Contract: "0x744d70fdbe2ba4cf95131626614a1763df805b9e",
+			},
+		},
+		{
+			Name:             "exponent value",
+			WhisperTimestamp: 30,
+			Valid:            false,
+			Message: protobuf.RequestTransaction{
+				Clock:    30,
+				Value:    "1e3",
+				Address:  "0x5ffa75ce51c3a7ebe23bde37b5e3a0143dfbcee0",
+				Contract: "0x744d70fdbe2ba4cf95131626614a1763df805b9e",
+			},
+		},
+		{
+			Name:             "underscore separated value",
+			WhisperTimestamp: 30,
+			Valid:            false,
+			Message: protobuf.RequestTransaction{
+				Clock:    30,
+				Value:    "1_000",
+				Address:  "0x5ffa75ce51c3a7ebe23bde37b5e3a0143dfbcee0",
+				Contract: "0x744d70fdbe2ba4cf95131626614a1763df805b9e",
+			},
+		},
+		{
+			Name:             "comma separated value",
+			WhisperTimestamp: 30,
+			Valid:            false,
+			Message: protobuf.RequestTransaction{
+				Clock:    30,
+				Value:    "1,000",
+				Address:  "0x5ffa75ce51c3a7ebe23bde37b5e3a0143dfbcee0",
+				Contract: "0x744d70fdbe2ba4cf95131626614a1763df805b9e",
+			},
+		},
+		{
+			Name:             "zero address",
+			WhisperTimestamp: 30,
+			Valid:            false,
+			Message: protobuf.RequestTransaction{
+				Clock:    30,
+				Value:    "1.5",
+				Address:  "0x0000000000000000000000000000000000000000",
+				Contract: "0x744d70fdbe2ba4cf95131626614a1763df805b9e",
+			},
+		},
+		{
+			Name:             "missing address",
+			WhisperTimestamp: 30,
+			Valid:            false,
+			Message: protobuf.RequestTransaction{
+				Clock:    30,
+				Value:    "1.5",
+				Contract: "0x744d70fdbe2ba4cf95131626614a1763df805b9e",
+			},
+		},
+		{
+			Name:             "address is contract",
+			WhisperTimestamp: 30,
+			Valid:            false,
+			Message: protobuf.RequestTransaction{
+				Clock:    30,
+				Value:    "1.5",
+				Address:  "0x744d70fdbe2ba4cf95131626614a1763df805b9e",
+				Contract: "0x744d70fdbe2ba4cf95131626614a1763df805b9e",
+			},
+		},
+		{
+			Name:             "address is contract with different case",
+			WhisperTimestamp: 30,
+			Valid:            false,
+			Message: protobuf.RequestTransaction{
+				Clock:    30,
+				Value:    "1.5",
+				Address:  "0x744D70FDBE2BA4CF95131626614A1763DF805B9E",
+				Contract: "0x744d70fdbe2ba4cf95131626614a1763df805b9e",
+			},
+		},
+		{
+			Name:             "no contract",
+			WhisperTimestamp: 30,
+			Valid:            true,
+			Message: protobuf.RequestTransaction{
+				Clock:   30,
+				Value:   "1.5",
+				Address: "0x5ffa75ce51c3a7ebe23bde37b5e3a0143dfbcee0",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		s.Run(tc.Name, func() {
+			err := ValidateReceivedRequestTransaction(&tc.Message, tc.WhisperTimestamp)
+			if tc.Valid {
+				s.Nil(err)
+			} else {
+				s.NotNil(err)
+			}
+		})
+	}
+}
+
+func (s *MessageValidatorSuite) TestValidateSendTransaction() {
+	signature := func(v byte) []byte {
+		signature := make([]byte, transactionSignatureLength)
+		signature[transactionSignatureLength-1] = v
+		return signature
+	}
+
+	testCases := []struct {
+		Name             string
+		WhisperTimestamp uint64
+		Valid            bool
+		Message          protobuf.SendTransaction
+	}{
+		{
+			Name:             "valid recovery id",
+			WhisperTimestamp: 30,
+			Valid:            true,
+			Message: protobuf.SendTransaction{
+				Clock:           30,
+				TransactionHash: "0x412a851ac2ae51cad34a56c8a9cfee55d577ac5e1ac71cf488a2f2093a373799",
+				Signature:       signature(27),
+			},
+		},
+		{
+			Name:             "valid yellow paper recovery id",
+			WhisperTimestamp: 30,
+			Valid:            true,
+			Message: protobuf.SendTransaction{
+				Clock:           30,
+				TransactionHash: "0x412a851ac2ae51cad34a56c8a9cfee55d577ac5e1ac71cf488a2f2093a373799",
+				Signature:       signature(1),
+			},
+		},
+		{
+			Name:             "invalid recovery id",
+			WhisperTimestamp: 30,
+			Valid:            false,
+			Message: protobuf.SendTransaction{
+				Clock:           30,
+				TransactionHash: "0x412a851ac2ae51cad34a56c8a9cfee55d577ac5e1ac71cf488a2f2093a373799",
+				Signature:       signature(29),
+			},
+		},
+		{
+			Name:             "invalid signature length",
+			WhisperTimestamp: 30,
+			Valid:            false,
+			Message: protobuf.SendTransaction{
+				Clock:           30,
+				TransactionHash: "0x412a851ac2ae51cad34a56c8a9cfee55d577ac5e1ac71cf488a2f2093a373799",
+				Signature:       []byte("signature"),
+			},
+		},
+		{
+			Name:             "missing signature",
+			WhisperTimestamp: 30,
+			Valid:            false,
+			Message: protobuf.SendTransaction{
+				Clock:           30,
+				TransactionHash: "0x412a851ac2ae51cad34a56c8a9cfee55d577ac5e1ac71cf488a2f2093a373799",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		s.Run(tc.Name, func() {
+			err := ValidateReceivedSendTransaction(&tc.Message, tc.WhisperTimestamp)
+			if tc.Valid {
+				s.Nil(err)
+			} else {
+				s.NotNil(err)
+			}
+		})
+	}
+}
+
+func (s *MessageValidatorSuite) TestValidateTransactionResponses() {
+	responses := []TransactionResponse{
+		&protobuf.AcceptRequestAddressForTransaction{Clock: 30, Id: "0x01", Address: "0x5ffa75ce51c3a7ebe23bde37b5e3a0143dfbcee0"},
+		&protobuf.DeclineRequestTransaction{Clock: 30, Id: "0x02"},
+		&protobuf.SendTransaction{Clock: 30, Id: "0x03"},
+	}
+	s.Equal([]error{nil, nil, nil}, ValidateReceivedTransactionResponses(responses))
+
+	responses = append(responses, &protobuf.DeclineRequestAddressForTransaction{Clock: 30, Id: "0x01"})
+	s.Equal([]error{nil, nil, nil, ErrDuplicateResponseID}, ValidateReceivedTransactionResponses(responses))
+}
+
+func (s *MessageValidatorSuite) TestValidateMembershipUpdateMessageDuplicateEvents() {
+	event1 := v1protocol.NewMemberJoinedEvent(30)
+	event1.Signature = []byte("signature-1")
+	event2 := v1protocol.NewNameChangedEvent("chat-name", 30)
+	event2.Signature = []byte("signature-2")
+
+	message := &v1protocol.MembershipUpdateMessage{Events: []v1protocol.MembershipUpdateEvent{event1, event2}}
+	s.Nil(ValidateMembershipUpdateMessage(message, 30))
+
+	message = &v1protocol.MembershipUpdateMessage{Events: []v1protocol.MembershipUpdateEvent{event1, event2, event1}}
+	s.Equal(ErrDuplicateMembershipEvent, ValidateMembershipUpdateMessage(message, 30))
+}
+
+func (s *MessageValidatorSuite) TestValidateAcceptRequestAddressForTransaction() {
+	testCases := []struct {
+		Name             string
+		WhisperTimestamp uint64
+		Valid            bool
+		Message          protobuf.AcceptRequestAddressForTransaction
+	}{
+		{
+			Name:             "valid message",
+			WhisperTimestamp: 30,
+			Valid:            true,
+			Message: protobuf.AcceptRequestAddressForTransaction{
+				Clock:   30,
+				Id:      "0x01",
+				Address: "0x5ffa75ce51c3a7ebe23bde37b5e3a0143dfbcee0",
+			},
+		},
+		{
+			Name:             "zero address",
+			WhisperTimestamp: 30,
+			Valid:            false,
+			Message: protobuf.AcceptRequestAddressForTransaction{
+				Clock:   30,
+				Id:      "0x01",
+				Address: "0x0000000000000000000000000000000000000000",
+			},
+		},
+		{
+			Name:             "missing id",
+			WhisperTimestamp: 30,
+			Valid:            false,
+			Message: protobuf.AcceptRequestAddressForTransaction{
+				Clock:   30,
+				Address: "0x5ffa75ce51c3a7ebe23bde37b5e3a0143dfbcee0",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		s.Run(tc.Name, func() {
+			err := ValidateReceivedAcceptRequestAddressForTransaction(&tc.Message, tc.WhisperTimestamp)
+			if tc.Valid {
+				s.Nil(err)
+			} else {
+				s.NotNil(err)
+			}
+		})
+	}
+}
+
+func (s *MessageValidatorSuite) TestValidatePublicKey() {
+	key, err := crypto.GenerateKey()
+	s.Require().NoError(err)
+
+	s.Nil(ValidatePublicKey(testPublicKey1))
+	s.Nil(ValidatePublicKey(types.EncodeHex(crypto.CompressPubkey(&key.PublicKey))))
+
+	s.Equal(ErrInvalidPublicKey, ValidatePublicKey(testPublicKey1[:len(testPublicKey1)-2]))
+	s.Equal(ErrInvalidPublicKey, ValidatePublicKey(testPublicKey1[2:]))
+	s.Equal(ErrInvalidPublicKey, ValidatePublicKey("0x"+strings.Repeat("z", 130)))
+	s.Equal(ErrInvalidPublicKey, ValidatePublicKey("0x04"+strings.Repeat("0", 128)))
+}
+
+func (s *MessageValidatorSuite) TestValidateMembershipUpdateMessageMembers() {
+	message := &v1protocol.MembershipUpdateMessage{Events: []v1protocol.MembershipUpdateEvent{
+		v1protocol.NewMembersAddedEvent([]string{testPublicKey1, testPublicKey2}, 30),
+	}}
+	s.Nil(ValidateMembershipUpdateMessage(message, 30))
+
+	message = &v1protocol.MembershipUpdateMessage{Events: []v1protocol.MembershipUpdateEvent{
+		v1protocol.NewMembersAddedEvent([]string{testPublicKey1, "not-a-key"}, 30),
+	}}
+	s.Equal(ErrInvalidPublicKey, ValidateMembershipUpdateMessage(message, 30))
+}
+
+func (s *MessageValidatorSuite) TestValidateMembershipUpdateMessageEmpty() {
+	message := &v1protocol.MembershipUpdateMessage{}
+	s.Equal(ErrEmptyMembershipUpdate, ValidateMembershipUpdateMessage(message, 30))
+
+	message = &v1protocol.MembershipUpdateMessage{Events: []v1protocol.MembershipUpdateEvent{
+		v1protocol.NewMemberJoinedEvent(30),
+	}}
+	s.Nil(ValidateMembershipUpdateMessage(message, 30))
+}
+
+func (s *MessageValidatorSuite) TestValidateBatchSummary() {
+	validMessage := &protobuf.ChatMessage{
+		ChatId:      "a",
+		Text:        "some-text",
+		Clock:       2,
+		Timestamp:   3,
+		MessageType: protobuf.ChatMessage_ONE_TO_ONE,
+		ContentType: protobuf.ChatMessage_TEXT_PLAIN,
+	}
+	emptyTextMessage := &protobuf.ChatMessage{
+		ChatId:      "a",
+		Clock:       2,
+		Timestamp:   3,
+		MessageType: protobuf.ChatMessage_ONE_TO_ONE,
+		ContentType: protobuf.ChatMessage_TEXT_PLAIN,
+	}
+	messages := []proto.Message{
+		validMessage,
+		emptyTextMessage,
+		&protobuf.DeclineRequestTransaction{Clock: 2, Id: "0x01"},
+		emptyTextMessage,
+		&protobuf.DeclineRequestTransaction{Clock: 2},
+		&protobuf.ContactUpdate{Clock: 2},
+		// Events that can't be decoded fail with wrapped errors
+		&protobuf.MembershipUpdateMessage{Events: [][]byte{[]byte("not-an-event")}},
+		&protobuf.MembershipUpdateMessage{Events: [][]byte{[]byte("not-an-event")}},
+	}
+
+	summary := ValidateBatchSummary(messages, 2)
+	s.Equal([]error{nil, ErrEmptyText, nil, ErrEmptyText, ErrEmptyMessageID, ErrUnsupportedMessageType}, summary.Results[:6])
+	s.NotNil(summary.Results[6])
+	s.NotNil(summary.Results[7])
+	s.Equal(2, summary.Valid)
+	s.Equal(6, summary.Rejected)
+	s.Equal(map[error]int{
+		ErrEmptyText:              2,
+		ErrEmptyMessageID:         1,
+		ErrUnsupportedMessageType: 1,
+		ErrOtherValidationFailure: 2,
+	}, summary.RejectedByReason)
+}
+
+func (s *MessageValidatorSuite) TestRejectionReason() {
+	s.Equal(ErrEmptyText, rejectionReason(ErrEmptyText))
+	s.Equal(ErrZeroClock, rejectionReason(fmt.Errorf("invalid message: %w", ErrZeroClock)))
+	s.Equal(ErrEmptyChatID, rejectionReason(ValidationErrors{ErrEmptyChatID, ErrEmptyText}))
+	s.Equal(ErrOtherValidationFailure, rejectionReason(errors.New("some error")))
+
+	// Aggregated errors are not hashable, so they can't be used as keys directly
+	summary := BatchValidationSummary{RejectedByReason: make(map[error]int)}
+	summary.RejectedByReason[rejectionReason(ValidationErrors{ErrEmptyChatID})]++
+	s.Equal(1, summary.RejectedByReason[ErrEmptyChatID])
+}
+
+func (s *MessageValidatorSuite) TestValidateReceivedWithTimingHook() {
+	var recorded []protobuf.ApplicationMetadataMessage_Type
+	hook := func(messageType protobuf.ApplicationMetadataMessage_Type, duration time.Duration) {
+		s.True(duration >= 0)
+		recorded = append(recorded, messageType)
+	}
+
+	s.Nil(ValidateReceivedWithTimingHook(&protobuf.DeclineRequestTransaction{Clock: 2, Id: "0x01"}, 2, hook))
+	s.Equal(ErrEmptyMembershipUpdate, ValidateReceivedWithTimingHook(&protobuf.MembershipUpdateMessage{}, 2, hook))
+	s.Equal(ErrUnsupportedMessageType, ValidateReceivedWithTimingHook(&protobuf.ContactUpdate{Clock: 2}, 2, hook))
+
+	s.Equal([]protobuf.ApplicationMetadataMessage_Type{
+		protobuf.ApplicationMetadataMessage_DECLINE_REQUEST_TRANSACTION,
+		protobuf.ApplicationMetadataMessage_MEMBERSHIP_UPDATE_MESSAGE,
+	}, recorded)
+}
+
+func (s *MessageValidatorSuite) TestValidateSyncInstallationContact() {
+	testCases := []struct {
+		Name             string
+		WhisperTimestamp uint64
+		Valid            bool
+		Message          protobuf.SyncInstallationContact
+	}{
+		{
+			Name:             "valid message",
+			WhisperTimestamp: 30,
+			Valid:            true,
+			Message: protobuf.SyncInstallationContact{
+				Clock: 30,
+				Id:    testPublicKey1,
+			},
+		},
+		{
+			Name:             "valid blocked contact",
+			WhisperTimestamp: 30,
+			Valid:            true,
+			Message: protobuf.SyncInstallationContact{
+				Clock:      30,
+				Id:         testPublicKey1,
+				SystemTags: []string{contactBlocked, contactRequestReceived},
+			},
+		},
+		// Contacts can be both added and blocked, see Messenger.Init
+		{
+			Name:             "added and blocked contact",
+			WhisperTimestamp: 30,
+			Valid:            true,
+			Message: protobuf.SyncInstallationContact{
+				Clock:      30,
+				Id:         testPublicKey1,
+				SystemTags: []string{contactAdded, contactBlocked},
+			},
+		},
+		{
+			Name:             "invalid id",
+			WhisperTimestamp: 30,
+			Valid:            false,
+			Message: protobuf.SyncInstallationContact{
+				Clock: 30,
+				Id:    "0x04",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		s.Run(tc.Name, func() {
+			err := ValidateReceivedSyncInstallationContact(&tc.Message, tc.WhisperTimestamp)
+			if tc.Valid {
+				s.Nil(err)
+			} else {
+				s.NotNil(err)
+			}
+		})
+	}
+}
+
+func (s *MessageValidatorSuite) TestValidatePairInstallationClockLookup() {
+	installationID := "6b3a7d1e-5f0c-4d9e-9c6b-2a8f4e1d7c35"
+	lastSeenClock := func(id string) (uint64, bool) {
+		if id == installationID {
+			return MinPlausibleClockValue + 10, true
+		}
+		return 0, false
+	}
+	message := func(id string, clock uint64) *protobuf.PairInstallation {
+		return &protobuf.PairInstallation{
+			Clock:          clock,
+			InstallationId: id,
+			DeviceType:     "ios",
+			Name:           "my-device",
+		}
+	}
+	whisperTimestamp := MinPlausibleClockValue + 20
+
+	s.Nil(ValidateReceivedPairInstallationWithClockLookup(message(installationID, MinPlausibleClockValue+11), whisperTimestamp, lastSeenClock))
+	s.Nil(ValidateReceivedPairInstallationWithClockLookup(message("0f3c2b9a-8d7e-4c61-a5b4-3e2d1c0b9a87", MinPlausibleClockValue), whisperTimestamp, lastSeenClock))
+	s.Equal(ErrNonMonotonicPairingClock, ValidateReceivedPairInstallationWithClockLookup(message(installationID, MinPlausibleClockValue+10), whisperTimestamp, lastSeenClock))
+	s.Equal(ErrNonMonotonicPairingClock, ValidateReceivedPairInstallationWithClockLookup(message(installationID, MinPlausibleClockValue+5), whisperTimestamp, lastSeenClock))
+}
@@ -121,6 +121,9 @@ type config struct {
 
 	verifyTransactionClient EthClient
 
+	validationTimingHook         ValidationTimingHook
+	chatMessageValidationOptions ChatMessageValidationOptions
+
 	logger *zap.Logger
 }
 
@@ -170,6 +173,23 @@ func WithVerifyTransactionClient(client EthClient) Option {
 	}
 }
 
+// WithValidationTimingHook sets a hook that is called with the duration of the
+// validation of every received message.
+func WithValidationTimingHook(h ValidationTimingHook) Option {
+	return func(c *config) error {
+		c.validationTimingHook = h
+		return nil
+	}
+}
+
+// WithChatMessageValidationOptions enables optional checks on received chat messages.
+func WithChatMessageValidationOptions(options ChatMessageValidationOptions) Option {
+	return func(c *config) error {
+		c.chatMessageValidationOptions = options
+		return nil
+	}
+}
+
 func WithDatabase(db *sql.DB) Option {
 	return func(c *config) error {
 		c.db = db
@@ -338,7 +358,7 @@ func NewMessenger(
 		return nil, errors.Wrap(err, "failed to create messageProcessor")
 	}
 
-	handler := newMessageHandler(identity, logger, &sqlitePersistence{db: database})
+	handler := newMessageHandler(identity, logger, &sqlitePersistence{db: database}, c.validationTimingHook, c.chatMessageValidationOptions)
 
 	messenger = &Messenger{
 		node:                       node,
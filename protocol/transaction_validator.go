@@ -81,6 +81,11 @@ func (t *TransactionValidator) verifyTransactionSignature(ctx context.Context, f
 	// We take a copy as EcRecover modifies the byte slice
 	signatureCopy := make([]byte, len(signature))
 	copy(signatureCopy, signature)
+	// EcRecover only accepts the 27/28 V of personal_sign, so a yellow paper
+	// 0/1 V is converted
+	if len(signatureCopy) == transactionSignatureLength && signatureCopy[transactionSignatureLength-1] < 27 {
+		signatureCopy[transactionSignatureLength-1] += 27
+	}
 	extractedAddress, err := crypto.EcRecover(ctx, signatureMaterial, signatureCopy)
 	if err != nil {
 		return err
@@ -439,3 +439,23 @@ func (s *TransactionValidatorSuite) TestValidateTransactions() {
 	}
 
 }
+
+func (s *TransactionValidatorSuite) TestVerifyTransactionSignatureRecoveryID() {
+	chatKey, err := crypto.GenerateKey()
+	s.Require().NoError(err)
+	walletKey, err := crypto.GenerateKey()
+	s.Require().NoError(err)
+	address := crypto.PubkeyToAddress(walletKey.PublicKey)
+	transactionHash := "0x53edbe74408c2eeed4e5493b3aac0c006d8a14b140975f4306dd35f5e1d245bc"
+
+	validator := NewTransactionValidator(nil, nil, nil, tt.MustCreateTestLogger())
+
+	signature, err := buildSignature(walletKey, &chatKey.PublicKey, transactionHash)
+	s.Require().NoError(err)
+	s.Require().NoError(validator.verifyTransactionSignature(context.Background(), &chatKey.PublicKey, address, transactionHash, signature))
+
+	// Yellow paper recovery id
+	signature[64] -= 27
+	s.Require().NoError(validator.verifyTransactionSignature(context.Background(), &chatKey.PublicKey, address, transactionHash, signature))
+	s.Require().Less(signature[64], byte(27), "signature must not be modified")
+}